@@ -0,0 +1,212 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// bandlimitedEntry is a memoized (harmonic count, normalization peak) pair
+// for one frequency.
+type bandlimitedEntry struct {
+	harmonics int
+	peak      float64
+}
+
+// bandlimitedNormCache memoizes, per frequency, the harmonic count and peak
+// absolute value of a band-limited partial sum. Both depend only on
+// frequency/sampleRate/maxHarmonics, so computing them once here keeps the
+// real-time Render path (called once per output sample) down to just the
+// O(k) partial-sum synthesis the additive series actually requires.
+type bandlimitedNormCache struct {
+	mu      sync.Mutex
+	entries map[float64]bandlimitedEntry
+}
+
+func newBandlimitedNormCache() *bandlimitedNormCache {
+	return &bandlimitedNormCache{entries: make(map[float64]bandlimitedEntry)}
+}
+
+// entryFor returns the cached harmonic count and normalization peak for
+// frequency, computing and memoizing them via computeHarmonics/computePeak
+// on first use.
+func (c *bandlimitedNormCache) entryFor(frequency float64, computeHarmonics func() int, computePeak func(harmonics int) float64) (int, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[frequency]; ok {
+		return e.harmonics, e.peak
+	}
+	harmonics := computeHarmonics()
+	peak := computePeak(harmonics)
+	c.entries[frequency] = bandlimitedEntry{harmonics: harmonics, peak: peak}
+	return harmonics, peak
+}
+
+// peakOverPeriod samples sum across one full period of frequency to find its
+// maximum absolute value, used to normalize a band-limited partial sum so
+// its peak matches the requested amplitude.
+func peakOverPeriod(frequency float64, sum func(t float64) float64) float64 {
+	if frequency <= 0 {
+		return 1
+	}
+	const samplesPerPeriod = 512
+	period := 1.0 / frequency
+	peak := 0.0
+	for i := 0; i < samplesPerPeriod; i++ {
+		t := period * float64(i) / samplesPerPeriod
+		if v := math.Abs(sum(t)); v > peak {
+			peak = v
+		}
+	}
+	if peak == 0 {
+		return 1
+	}
+	return peak
+}
+
+// squareHarmonicCount returns the largest K with (2K-1)*frequency strictly
+// below Nyquist for sampleRate, capped at maxHarmonics when maxHarmonics > 0.
+func squareHarmonicCount(frequency float64, sampleRate, maxHarmonics int) int {
+	if frequency <= 0 {
+		return 1
+	}
+	nyquist := float64(sampleRate) / 2
+	k := int(math.Floor((nyquist/frequency + 1) / 2))
+	if k < 1 {
+		k = 1
+	}
+	// The floor above can land exactly on Nyquist (e.g. frequency ==
+	// nyquist/5 gives k=3 with (2*3-1)*frequency == nyquist), which the
+	// request's strict "<" excludes.
+	for k > 1 && float64(2*k-1)*frequency >= nyquist {
+		k--
+	}
+	if maxHarmonics > 0 && k > maxHarmonics {
+		k = maxHarmonics
+	}
+	return k
+}
+
+// squarePartialSum sums sin(2*pi*(2k-1)*f*t+phase)/(2k-1) for k=1..k.
+func squarePartialSum(t, frequency, phase float64, k int) float64 {
+	sum := 0.0
+	for i := 1; i <= k; i++ {
+		n := float64(2*i - 1)
+		sum += math.Sin(2*math.Pi*n*frequency*t+phase) / n
+	}
+	return sum
+}
+
+// BandlimitedSquare returns a Waveform that synthesizes a square wave as an
+// additive Fourier series of odd harmonics up to the Nyquist limit for
+// sampleRate, eliminating the aliasing the naive SquareWave produces.
+// maxHarmonics caps the series for deliberate lo-fi timbres; 0 means no cap
+// beyond Nyquist.
+func BandlimitedSquare(sampleRate, maxHarmonics int) Waveform {
+	cache := newBandlimitedNormCache()
+	return func(t, frequency, amplitude, phase float64) float64 {
+		k, peak := cache.entryFor(frequency,
+			func() int { return squareHarmonicCount(frequency, sampleRate, maxHarmonics) },
+			func(k int) float64 {
+				return peakOverPeriod(frequency, func(t float64) float64 {
+					return squarePartialSum(t, frequency, 0, k)
+				})
+			})
+		return amplitude * squarePartialSum(t, frequency, phase, k) / peak
+	}
+}
+
+// triangleMaxOddHarmonic returns the largest odd k with k*frequency below
+// Nyquist for sampleRate, counting at most maxHarmonics terms when
+// maxHarmonics > 0.
+func triangleMaxOddHarmonic(frequency float64, sampleRate, maxHarmonics int) int {
+	if frequency <= 0 {
+		return 1
+	}
+	nyquist := float64(sampleRate) / 2
+	maxK, count := 1, 0
+	for k := 1; float64(k)*frequency < nyquist; k += 2 {
+		maxK = k
+		count++
+		if maxHarmonics > 0 && count >= maxHarmonics {
+			break
+		}
+	}
+	return maxK
+}
+
+// trianglePartialSum sums (-1)^((k-1)/2)*sin(2*pi*k*f*t+phase)/k^2 over odd
+// k from 1 to maxK.
+func trianglePartialSum(t, frequency, phase float64, maxK int) float64 {
+	sum, sign := 0.0, 1.0
+	for k := 1; k <= maxK; k += 2 {
+		n := float64(k)
+		sum += sign * math.Sin(2*math.Pi*n*frequency*t+phase) / (n * n)
+		sign = -sign
+	}
+	return sum
+}
+
+// BandlimitedTriangle returns a Waveform that synthesizes a triangle wave as
+// an additive Fourier series of odd harmonics up to the Nyquist limit for
+// sampleRate. maxHarmonics caps the number of terms; 0 means no cap beyond
+// Nyquist.
+func BandlimitedTriangle(sampleRate, maxHarmonics int) Waveform {
+	cache := newBandlimitedNormCache()
+	return func(t, frequency, amplitude, phase float64) float64 {
+		maxK, peak := cache.entryFor(frequency,
+			func() int { return triangleMaxOddHarmonic(frequency, sampleRate, maxHarmonics) },
+			func(maxK int) float64 {
+				return peakOverPeriod(frequency, func(t float64) float64 {
+					return trianglePartialSum(t, frequency, 0, maxK)
+				})
+			})
+		return amplitude * trianglePartialSum(t, frequency, phase, maxK) / peak
+	}
+}
+
+// sawHarmonicCount returns the largest k with k*frequency below Nyquist for
+// sampleRate, capped at maxHarmonics when maxHarmonics > 0.
+func sawHarmonicCount(frequency float64, sampleRate, maxHarmonics int) int {
+	if frequency <= 0 {
+		return 1
+	}
+	nyquist := float64(sampleRate) / 2
+	k := 0
+	for float64(k+1)*frequency < nyquist {
+		k++
+	}
+	if k < 1 {
+		k = 1
+	}
+	if maxHarmonics > 0 && k > maxHarmonics {
+		k = maxHarmonics
+	}
+	return k
+}
+
+// sawPartialSum sums sin(2*pi*k*f*t+phase)/k for k=1..k.
+func sawPartialSum(t, frequency, phase float64, k int) float64 {
+	sum := 0.0
+	for i := 1; i <= k; i++ {
+		n := float64(i)
+		sum += math.Sin(2*math.Pi*n*frequency*t+phase) / n
+	}
+	return sum
+}
+
+// BandlimitedSaw returns a Waveform that synthesizes a sawtooth wave as an
+// additive Fourier series of harmonics up to the Nyquist limit for
+// sampleRate. maxHarmonics caps the series; 0 means no cap beyond Nyquist.
+func BandlimitedSaw(sampleRate, maxHarmonics int) Waveform {
+	cache := newBandlimitedNormCache()
+	return func(t, frequency, amplitude, phase float64) float64 {
+		k, peak := cache.entryFor(frequency,
+			func() int { return sawHarmonicCount(frequency, sampleRate, maxHarmonics) },
+			func(k int) float64 {
+				return peakOverPeriod(frequency, func(t float64) float64 {
+					return sawPartialSum(t, frequency, 0, k)
+				})
+			})
+		return amplitude * sawPartialSum(t, frequency, phase, k) / peak
+	}
+}
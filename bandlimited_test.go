@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSquareHarmonicCountExcludesExactNyquist covers the off-by-one
+// regression: frequency == nyquist/5 makes the naive floor((nyquist/f+1)/2)
+// estimate land a harmonic exactly on Nyquist, which must be excluded.
+func TestSquareHarmonicCountExcludesExactNyquist(t *testing.T) {
+	// sampleRate=10 -> nyquist=5, frequency=1 -> naive k=3 puts
+	// (2*3-1)*1 == 5 == nyquist, so the real answer is k=2.
+	if got := squareHarmonicCount(1, 10, 0); got != 2 {
+		t.Errorf("squareHarmonicCount(1, 10, 0) = %d, want 2", got)
+	}
+}
+
+func TestSquareHarmonicCountRespectsMaxHarmonics(t *testing.T) {
+	if got := squareHarmonicCount(100, 44100, 3); got != 3 {
+		t.Errorf("squareHarmonicCount(100, 44100, 3) = %d, want 3", got)
+	}
+}
+
+func TestSquareHarmonicCountAllBelowNyquist(t *testing.T) {
+	k := squareHarmonicCount(440, 44100, 0)
+	nyquist := 44100.0 / 2
+	if got := float64(2*k-1) * 440; got >= nyquist {
+		t.Errorf("highest harmonic %v >= nyquist %v", got, nyquist)
+	}
+	if got := float64(2*(k+1)-1) * 440; got < nyquist {
+		t.Errorf("k=%d is not the largest valid harmonic count", k)
+	}
+}
+
+func TestTriangleMaxOddHarmonicIsOdd(t *testing.T) {
+	maxK := triangleMaxOddHarmonic(440, 44100, 0)
+	if maxK%2 == 0 {
+		t.Errorf("triangleMaxOddHarmonic(440, 44100, 0) = %d, want odd", maxK)
+	}
+}
+
+func TestTriangleMaxOddHarmonicRespectsMaxHarmonics(t *testing.T) {
+	// maxHarmonics counts terms, not the harmonic number itself: capping at 2
+	// terms (k=1, k=3) should stop at maxK=3.
+	if got := triangleMaxOddHarmonic(100, 44100, 2); got != 3 {
+		t.Errorf("triangleMaxOddHarmonic(100, 44100, 2) = %d, want 3", got)
+	}
+}
+
+func TestSawHarmonicCountAllBelowNyquist(t *testing.T) {
+	k := sawHarmonicCount(440, 44100, 0)
+	nyquist := 44100.0 / 2
+	if got := float64(k) * 440; got >= nyquist {
+		t.Errorf("highest harmonic %v >= nyquist %v", got, nyquist)
+	}
+	if got := float64(k+1) * 440; got < nyquist {
+		t.Errorf("k=%d is not the largest valid harmonic count", k)
+	}
+}
+
+func TestSawHarmonicCountRespectsMaxHarmonics(t *testing.T) {
+	if got := sawHarmonicCount(100, 44100, 5); got != 5 {
+		t.Errorf("sawHarmonicCount(100, 44100, 5) = %d, want 5", got)
+	}
+}
+
+func TestBandlimitedSquarePeakMatchesAmplitude(t *testing.T) {
+	wave := BandlimitedSquare(44100, 0)
+	const amplitude = fullScaleInt16
+	const frequency = 440.0
+
+	peak := 0.0
+	const samples = 1024
+	period := 1.0 / frequency
+	for i := 0; i < samples; i++ {
+		t := period * float64(i) / samples
+		if v := math.Abs(wave(t, frequency, amplitude, 0)); v > peak {
+			peak = v
+		}
+	}
+
+	if math.Abs(peak-amplitude) > amplitude*0.05 {
+		t.Errorf("peak = %v, want close to amplitude %v", peak, amplitude)
+	}
+}
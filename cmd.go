@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// gen and play are implemented here as subcommands of the single main
+// package (os.Args[1] dispatch to genMain/playMain below) rather than as
+// separate cmd/gen and cmd/play binaries. That's a deliberate simplification
+// given this module has no internal library package to import from two
+// command directories yet; if sinetastic grows a real library/cmd split,
+// these should become actual cmd/gen and cmd/play binaries importing it.
+
+// waveformKinds maps the -wave flag value accepted by the gen and play
+// subcommands to the Waveform implementation it selects. "silence" is
+// included so the gen subcommand can produce fixed-length silence fixtures
+// for audio pipeline testing.
+var waveformKinds = map[string]Waveform{
+	"sine":     SineWave,
+	"square":   SquareWave,
+	"triangle": TriangleWave,
+	"silence":  SilenceWave,
+}
+
+// parseWaveformKind looks up name in waveformKinds, returning an error that
+// lists the valid kinds if it isn't found. The "bl-" prefixed kinds build a
+// band-limited additive Fourier series for sampleRate, capped at
+// maxHarmonics terms when maxHarmonics > 0.
+func parseWaveformKind(name string, sampleRate, maxHarmonics int) (Waveform, error) {
+	switch name {
+	case "bl-square":
+		return BandlimitedSquare(sampleRate, maxHarmonics), nil
+	case "bl-triangle":
+		return BandlimitedTriangle(sampleRate, maxHarmonics), nil
+	case "bl-saw":
+		return BandlimitedSaw(sampleRate, maxHarmonics), nil
+	}
+
+	wave, ok := waveformKinds[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown waveform %q (want sine, square, triangle, silence, bl-square, bl-triangle or bl-saw)", name)
+	}
+	return wave, nil
+}
+
+// genMain implements the "gen" subcommand: render a waveform (or silence)
+// straight to a sound file, with no playback.
+func genMain(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	waveName := fs.String("wave", "sine", "waveform kind: sine, square, triangle, silence, bl-square, bl-triangle or bl-saw")
+	freq := fs.Float64("freq", 440.0, "frequency in Hz")
+	amp := fs.Float64("amp", float64(amplitude), "amplitude (full scale is 32767)")
+	phase := fs.Float64("phase", 0.0, "phase offset in radians")
+	rate := fs.Int("rate", sampleRate, "sample rate in Hz")
+	bits := fs.Int("bits", bitDepth, "bit depth: 16, 24 or 32 (32 means float)")
+	channels := fs.Int("channels", 1, "number of output channels")
+	dur := fs.Duration("duration", duration, "duration, as a Go duration string (e.g. 500ms, 2s)")
+	format := fs.String("format", "wav", "container format: wav or aiff")
+	out := fs.String("out", "out.wav", "output filename")
+	maxHarmonics := fs.Int("maxharmonics", 0, "cap on harmonics for bl-square/bl-triangle/bl-saw; 0 means up to Nyquist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wave, err := parseWaveformKind(*waveName, *rate, *maxHarmonics)
+	if err != nil {
+		return err
+	}
+
+	mono := GenerateWave(wave, *freq, *amp, *phase, *rate, *dur)
+	monoF := int16ToFloat64(mono)
+
+	channelBufs := make([][]float64, *channels)
+	for i := range channelBufs {
+		channelBufs[i] = monoF
+	}
+
+	var enc Encoder = PCMEncoder{}
+	switch *format {
+	case "aiff":
+		return enc.WriteAIFF(*out, channelBufs, *rate)
+	case "wav":
+		switch *bits {
+		case 24:
+			return enc.WriteWAVInt24(*out, channelBufs, *rate)
+		case 32:
+			return enc.WriteWAVFloat32(*out, channelBufs, *rate)
+		default:
+			return enc.WriteWAVInt16(*out, channelBufs, *rate)
+		}
+	default:
+		return fmt.Errorf("unknown format %q (want wav or aiff)", *format)
+	}
+}
+
+// playMain implements the "play" subcommand: stream a single waveform to
+// the default audio device for the given duration.
+func playMain(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	waveName := fs.String("wave", "sine", "waveform kind: sine, square, triangle, silence, bl-square, bl-triangle or bl-saw")
+	freq := fs.Float64("freq", 440.0, "frequency in Hz")
+	amp := fs.Float64("amp", float64(amplitude), "amplitude (full scale is 32767)")
+	phase := fs.Float64("phase", 0.0, "phase offset in radians")
+	rate := fs.Int("rate", sampleRate, "sample rate in Hz")
+	dur := fs.Duration("duration", duration, "duration, as a Go duration string (e.g. 500ms, 2s)")
+	maxHarmonics := fs.Int("maxharmonics", 0, "cap on harmonics for bl-square/bl-triangle/bl-saw; 0 means up to Nyquist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wave, err := parseWaveformKind(*waveName, *rate, *maxHarmonics)
+	if err != nil {
+		return err
+	}
+
+	osc := NewOscillator(wave, *freq, *amp, *phase, *rate)
+	return PlayWave(osc, *rate, *dur)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+// WAV fmt-chunk format tags.
+const (
+	wavIntPCM   = 1
+	wavFloatPCM = 3
+)
+
+// Encoder writes a set of per-channel float64 sample buffers (one []float64
+// per channel, all the same length) to a sound file in a specific
+// container/sample-format combination.
+type Encoder interface {
+	WriteWAVInt16(filename string, channels [][]float64, sampleRate int) error
+	WriteWAVInt24(filename string, channels [][]float64, sampleRate int) error
+	WriteWAVFloat32(filename string, channels [][]float64, sampleRate int) error
+	WriteAIFF(filename string, channels [][]float64, sampleRate int) error
+}
+
+// PCMEncoder is the default Encoder, writing plain WAV or AIFF containers
+// with no compression.
+type PCMEncoder struct{}
+
+// WriteWAVInt16 writes channels as a 16-bit integer PCM WAV file.
+func (PCMEncoder) WriteWAVInt16(filename string, channels [][]float64, sampleRate int) error {
+	return writeWAV(filename, channels, sampleRate, wavIntPCM, 16)
+}
+
+// WriteWAVInt24 writes channels as a 24-bit integer PCM WAV file.
+func (PCMEncoder) WriteWAVInt24(filename string, channels [][]float64, sampleRate int) error {
+	return writeWAV(filename, channels, sampleRate, wavIntPCM, 24)
+}
+
+// WriteWAVFloat32 writes channels as an IEEE float32 PCM WAV file.
+func (PCMEncoder) WriteWAVFloat32(filename string, channels [][]float64, sampleRate int) error {
+	return writeWAV(filename, channels, sampleRate, wavFloatPCM, 32)
+}
+
+// WriteAIFF writes channels as a 16-bit big-endian PCM AIFF file.
+func (PCMEncoder) WriteAIFF(filename string, channels [][]float64, sampleRate int) error {
+	return writeAIFF(filename, channels, sampleRate)
+}
+
+// writeWAV writes an interleaved, little-endian WAV file in the given
+// format/bitsPerSample combination. format is the fmt-chunk audio format
+// tag (wavIntPCM or wavFloatPCM).
+func writeWAV(filename string, channels [][]float64, sampleRate, format, bitsPerSample int) error {
+	if len(channels) == 0 {
+		return fmt.Errorf("writeWAV: no channels to write")
+	}
+
+	numChannels := len(channels)
+	numFrames := len(channels[0])
+	bytesPerSample := bitsPerSample / 8
+	blockAlign := numChannels * bytesPerSample
+	byteRate := sampleRate * blockAlign
+	dataSize := numFrames * blockAlign
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	write := func(v interface{}) error { return binary.Write(f, binary.LittleEndian, v) }
+
+	if _, err := f.WriteString("RIFF"); err != nil {
+		return err
+	}
+	if err := write(uint32(36 + dataSize)); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("WAVE"); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString("fmt "); err != nil {
+		return err
+	}
+	if err := write(uint32(16)); err != nil {
+		return err
+	}
+	if err := write(uint16(format)); err != nil {
+		return err
+	}
+	if err := write(uint16(numChannels)); err != nil {
+		return err
+	}
+	if err := write(uint32(sampleRate)); err != nil {
+		return err
+	}
+	if err := write(uint32(byteRate)); err != nil {
+		return err
+	}
+	if err := write(uint16(blockAlign)); err != nil {
+		return err
+	}
+	if err := write(uint16(bitsPerSample)); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString("data"); err != nil {
+		return err
+	}
+	if err := write(uint32(dataSize)); err != nil {
+		return err
+	}
+
+	for i := 0; i < numFrames; i++ {
+		for _, ch := range channels {
+			sample := ch[i]
+			switch {
+			case format == wavFloatPCM:
+				if err := write(QuantizeFloat32(sample)); err != nil {
+					return err
+				}
+			case bitsPerSample == 24:
+				if err := writeInt24LE(f, QuantizeInt24(sample)); err != nil {
+					return err
+				}
+			default:
+				if err := write(QuantizeInt16(sample)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	fmt.Printf("Written %s\n", filename)
+	return nil
+}
+
+// writeInt24LE writes a 24-bit two's-complement sample in little-endian order.
+func writeInt24LE(f *os.File, sample int32) error {
+	b := []byte{
+		byte(sample),
+		byte(sample >> 8),
+		byte(sample >> 16),
+	}
+	_, err := f.Write(b)
+	return err
+}
+
+// writeAIFF writes an interleaved, big-endian, 16-bit PCM AIFF file using
+// FORM/COMM/SSND chunks.
+func writeAIFF(filename string, channels [][]float64, sampleRate int) error {
+	if len(channels) == 0 {
+		return fmt.Errorf("writeAIFF: no channels to write")
+	}
+
+	numChannels := len(channels)
+	numFrames := len(channels[0])
+	const bitsPerSample = 16
+	dataSize := numFrames * numChannels * (bitsPerSample / 8)
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	write := func(v interface{}) error { return binary.Write(f, binary.BigEndian, v) }
+
+	formSize := 4 + (8 + 18) + (8 + 8 + dataSize)
+
+	if _, err := f.WriteString("FORM"); err != nil {
+		return err
+	}
+	if err := write(uint32(formSize)); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("AIFF"); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString("COMM"); err != nil {
+		return err
+	}
+	if err := write(uint32(18)); err != nil {
+		return err
+	}
+	if err := write(uint16(numChannels)); err != nil {
+		return err
+	}
+	if err := write(uint32(numFrames)); err != nil {
+		return err
+	}
+	if err := write(uint16(bitsPerSample)); err != nil {
+		return err
+	}
+	if _, err := f.Write(encodeIEEEExtended(float64(sampleRate))); err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString("SSND"); err != nil {
+		return err
+	}
+	if err := write(uint32(8 + dataSize)); err != nil {
+		return err
+	}
+	if err := write(uint32(0)); err != nil { // offset
+		return err
+	}
+	if err := write(uint32(0)); err != nil { // blockSize
+		return err
+	}
+
+	for i := 0; i < numFrames; i++ {
+		for _, ch := range channels {
+			if err := write(QuantizeInt16(ch[i])); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Written %s\n", filename)
+	return nil
+}
+
+// encodeIEEEExtended encodes f as an 80-bit IEEE 754 extended-precision
+// float, the format AIFF's COMM chunk requires for the sample rate field.
+func encodeIEEEExtended(f float64) []byte {
+	buf := make([]byte, 10)
+	if f == 0 {
+		return buf
+	}
+
+	sign := 0
+	if f < 0 {
+		sign = 0x8000
+		f = -f
+	}
+
+	frac, exp := math.Frexp(f)
+	exp += 16382
+	frac *= 1 << 64
+	mantissa := uint64(frac)
+
+	binary.BigEndian.PutUint16(buf[0:2], uint16(exp)|uint16(sign))
+	binary.BigEndian.PutUint64(buf[2:10], mantissa)
+	return buf
+}
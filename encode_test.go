@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteWAVHeaderLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	channels := [][]float64{{100, -200, 300}}
+	if err := writeWAV(path, channels, 44100, wavIntPCM, 16); err != nil {
+		t.Fatalf("writeWAV: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if string(data[0:4]) != "RIFF" {
+		t.Fatalf("chunk ID = %q, want RIFF", data[0:4])
+	}
+	if string(data[8:12]) != "WAVE" {
+		t.Fatalf("format = %q, want WAVE", data[8:12])
+	}
+	if string(data[12:16]) != "fmt " {
+		t.Fatalf("subchunk1 ID = %q, want \"fmt \"", data[12:16])
+	}
+
+	if got := binary.LittleEndian.Uint16(data[20:22]); got != wavIntPCM {
+		t.Errorf("audio format = %d, want %d", got, wavIntPCM)
+	}
+	if got := binary.LittleEndian.Uint16(data[22:24]); got != 1 {
+		t.Errorf("num channels = %d, want 1", got)
+	}
+	if got := binary.LittleEndian.Uint32(data[24:28]); got != 44100 {
+		t.Errorf("sample rate = %d, want 44100", got)
+	}
+	if got := binary.LittleEndian.Uint16(data[34:36]); got != 16 {
+		t.Errorf("bits per sample = %d, want 16", got)
+	}
+
+	if string(data[36:40]) != "data" {
+		t.Fatalf("subchunk2 ID = %q, want data", data[36:40])
+	}
+	if got := binary.LittleEndian.Uint32(data[40:44]); got != 3*2 {
+		t.Errorf("data size = %d, want 6", got)
+	}
+
+	if got := int16(binary.LittleEndian.Uint16(data[44:46])); got != 100 {
+		t.Errorf("first sample = %d, want 100", got)
+	}
+}
+
+func TestWriteWAVFloat32UsesFloatFormatTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	channels := [][]float64{{fullScaleInt16}}
+	if err := writeWAV(path, channels, 44100, wavFloatPCM, 32); err != nil {
+		t.Fatalf("writeWAV: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if got := binary.LittleEndian.Uint16(data[20:22]); got != wavFloatPCM {
+		t.Errorf("audio format = %d, want %d", got, wavFloatPCM)
+	}
+	if got := binary.LittleEndian.Uint16(data[34:36]); got != 32 {
+		t.Errorf("bits per sample = %d, want 32", got)
+	}
+
+	bits := binary.LittleEndian.Uint32(data[44:48])
+	if got := math.Float32frombits(bits); got < 0.99 || got > 1.01 {
+		t.Errorf("first sample = %v, want ~1.0", got)
+	}
+}
+
+func TestWriteAIFFHeaderLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.aiff")
+	channels := [][]float64{{100, -200}}
+	if err := writeAIFF(path, channels, 44100); err != nil {
+		t.Fatalf("writeAIFF: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if string(data[0:4]) != "FORM" {
+		t.Fatalf("chunk ID = %q, want FORM", data[0:4])
+	}
+	if string(data[8:12]) != "AIFF" {
+		t.Fatalf("format = %q, want AIFF", data[8:12])
+	}
+	if string(data[12:16]) != "COMM" {
+		t.Fatalf("subchunk1 ID = %q, want COMM", data[12:16])
+	}
+
+	if got := binary.BigEndian.Uint16(data[20:22]); got != 1 {
+		t.Errorf("num channels = %d, want 1", got)
+	}
+	if got := binary.BigEndian.Uint32(data[22:26]); got != 2 {
+		t.Errorf("num sample frames = %d, want 2", got)
+	}
+	if got := binary.BigEndian.Uint16(data[26:28]); got != 16 {
+		t.Errorf("sample size = %d, want 16", got)
+	}
+
+	const ssndOffset = 12 + 8 + 18 // FORM header + COMM header + COMM body
+	if string(data[ssndOffset:ssndOffset+4]) != "SSND" {
+		t.Fatalf("subchunk2 ID = %q, want SSND", data[ssndOffset:ssndOffset+4])
+	}
+
+	sampleOffset := ssndOffset + 8 + 8 // SSND header + offset/blockSize
+	if got := int16(binary.BigEndian.Uint16(data[sampleOffset : sampleOffset+2])); got != 100 {
+		t.Errorf("first sample = %d, want 100", got)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import "time"
+
+// Envelope describes a standard ADSR amplitude envelope: Attack and Decay
+// ramp in, Sustain holds a level while the note is held, and Release ramps
+// back to zero after the note's Duration ends — so a note's sound can
+// extend past its nominal end while it rings out.
+type Envelope struct {
+	Attack  time.Duration
+	Decay   time.Duration
+	Sustain float64 // sustain level, 0..1
+	Release time.Duration
+}
+
+// level returns the envelope's amplitude multiplier (0..1) elapsed time
+// into a note lasting noteDuration.
+//
+// Release always starts at releaseStart := max(noteDuration, Attack+Decay),
+// not at noteDuration itself: for a short, percussive note under a slower
+// envelope, Attack+Decay can run past noteDuration, and starting the
+// release clock at noteDuration in that case would make it elapse before
+// decay even finishes, snapping the note straight to silence instead of
+// tapering through Release.
+func (e Envelope) level(elapsed, noteDuration time.Duration) float64 {
+	decayEnd := e.Attack + e.Decay
+	releaseStart := noteDuration
+	if decayEnd > releaseStart {
+		releaseStart = decayEnd
+	}
+
+	switch {
+	case elapsed < 0:
+		return 0
+	case elapsed < e.Attack:
+		if e.Attack == 0 {
+			return 1
+		}
+		return float64(elapsed) / float64(e.Attack)
+	case elapsed < decayEnd:
+		if e.Decay == 0 {
+			return e.Sustain
+		}
+		t := float64(elapsed-e.Attack) / float64(e.Decay)
+		return 1 - t*(1-e.Sustain)
+	case elapsed < releaseStart:
+		return e.Sustain
+	case elapsed < releaseStart+e.Release:
+		if e.Release == 0 {
+			return 0
+		}
+		t := float64(elapsed-releaseStart) / float64(e.Release)
+		return e.Sustain * (1 - t)
+	default:
+		return 0
+	}
+}
+
+// Note is one event in a Sequencer score: play Waveform at Freq starting at
+// Start and held for Duration, shaped by Env. Env.Release may extend the
+// note's audible tail past Start+Duration.
+type Note struct {
+	Freq     float64
+	Start    time.Duration
+	Duration time.Duration
+	Waveform Waveform
+	Env      Envelope
+}
+
+// end returns when this note, including its release tail, stops
+// contributing samples. Release starts at max(Duration, Attack+Decay), not
+// at Duration itself — see Envelope.level — so the tail must be measured
+// from there too.
+func (n Note) end() time.Duration {
+	releaseStart := n.Duration
+	if decayEnd := n.Env.Attack + n.Env.Decay; decayEnd > releaseStart {
+		releaseStart = decayEnd
+	}
+	return n.Start + releaseStart + n.Env.Release
+}
+
+// Sequencer renders a declarative score, a slice of Notes, into a single
+// waveform at SampleRate.
+type Sequencer struct {
+	SampleRate int
+}
+
+// NewSequencer creates a Sequencer rendering at sampleRate.
+func NewSequencer(sampleRate int) *Sequencer {
+	return &Sequencer{SampleRate: sampleRate}
+}
+
+// Render renders notes into a single []int16 buffer, long enough to hold
+// every note's full release tail. Overlapping notes are summed through the
+// same peak-normalizing mixer CombineWaves uses, so overlapping release
+// tails don't clip.
+func (s *Sequencer) Render(notes []Note) []int16 {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	var totalDuration time.Duration
+	for _, n := range notes {
+		if end := n.end(); end > totalDuration {
+			totalDuration = end
+		}
+	}
+	numSamples := int(totalDuration.Seconds() * float64(s.SampleRate))
+
+	tracks := make([][]float64, len(notes))
+	for i, n := range notes {
+		tracks[i] = s.renderNote(n, numSamples)
+	}
+
+	mixed := CombineWaves(DefaultMixOptions(), tracks...)
+
+	wave := make([]int16, len(mixed))
+	for i, sample := range mixed {
+		wave[i] = QuantizeInt16(sample)
+	}
+	return wave
+}
+
+// renderNote renders a single Note's contribution into a numSamples-long
+// buffer, silent outside [Start, n.end()).
+func (s *Sequencer) renderNote(n Note, numSamples int) []float64 {
+	buf := make([]float64, numSamples)
+
+	startSample := int(n.Start.Seconds() * float64(s.SampleRate))
+	endSample := int(n.end().Seconds() * float64(s.SampleRate))
+	if endSample > numSamples {
+		endSample = numSamples
+	}
+
+	for i := startSample; i < endSample; i++ {
+		t := float64(i) / float64(s.SampleRate)
+		elapsed := time.Duration(float64(i-startSample) / float64(s.SampleRate) * float64(time.Second))
+		level := n.Env.level(elapsed, n.Duration)
+		buf[i] = level * n.Waveform(t, n.Freq, amplitude, 0)
+	}
+
+	return buf
+}
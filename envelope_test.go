@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEnvelopeLevelADSRStages(t *testing.T) {
+	env := Envelope{Attack: 100 * time.Millisecond, Decay: 100 * time.Millisecond, Sustain: 0.5, Release: 100 * time.Millisecond}
+	noteDuration := 500 * time.Millisecond
+
+	cases := []struct {
+		name    string
+		elapsed time.Duration
+		want    float64
+	}{
+		{"before start", -time.Millisecond, 0},
+		{"attack start", 0, 0},
+		{"attack end", 100 * time.Millisecond, 1},
+		{"decay end", 200 * time.Millisecond, 0.5},
+		{"sustain hold", 300 * time.Millisecond, 0.5},
+		{"release start", noteDuration, 0.5},
+		{"release end", noteDuration + 100*time.Millisecond, 0},
+		{"after release", noteDuration + 200*time.Millisecond, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := env.level(c.elapsed, noteDuration); math.Abs(got-c.want) > 1e-9 {
+				t.Errorf("level(%v, %v) = %v, want %v", c.elapsed, noteDuration, got, c.want)
+			}
+		})
+	}
+}
+
+// TestEnvelopeLevelShortNoteTapersInsteadOfClicking covers the click-on-
+// short-notes regression: a note whose Attack+Decay outlasts its Duration
+// must still taper through Release instead of snapping straight to
+// silence once Attack+Decay completes.
+func TestEnvelopeLevelShortNoteTapersInsteadOfClicking(t *testing.T) {
+	env := Envelope{Attack: 100 * time.Millisecond, Decay: 200 * time.Millisecond, Sustain: 0.4, Release: 100 * time.Millisecond}
+	noteDuration := 50 * time.Millisecond
+
+	decayEnd := env.Attack + env.Decay   // 300ms, past noteDuration
+	releaseEnd := decayEnd + env.Release // 400ms
+
+	if got := env.level(decayEnd, noteDuration); math.Abs(got-env.Sustain) > 1e-9 {
+		t.Fatalf("level at decay end = %v, want Sustain %v", got, env.Sustain)
+	}
+
+	mid := env.level(decayEnd+50*time.Millisecond, noteDuration)
+	if mid <= 0 || mid >= env.Sustain {
+		t.Fatalf("level mid-release = %v, want strictly between 0 and %v", mid, env.Sustain)
+	}
+
+	if got := env.level(releaseEnd, noteDuration); got != 0 {
+		t.Fatalf("level at release end = %v, want 0", got)
+	}
+
+	if got := (Note{Duration: noteDuration, Env: env}).end(); got != releaseEnd {
+		t.Fatalf("Note.end() = %v, want %v", got, releaseEnd)
+	}
+}
@@ -2,15 +2,10 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math"
 	"os"
 	"time"
-
-	"github.com/go-audio/audio"
-	"github.com/go-audio/wav"
-	"github.com/veandco/go-sdl2/mix"
 )
 
 const (
@@ -41,113 +36,57 @@ func TriangleWave(t float64, frequency float64, amplitude float64, phase float64
 	return (2 * amplitude / math.Pi) * math.Asin(math.Sin(2.0*math.Pi*frequency*t+phase))
 }
 
-// GenerateWave generates the samples for a given waveform function, frequency, and duration
+// SilenceWave always returns 0, regardless of time, frequency, amplitude or
+// phase. It exists so callers can generate fixed-length silence fixtures
+// through the same Waveform/GenerateWave machinery as the other waveforms.
+func SilenceWave(t float64, frequency float64, amplitude float64, phase float64) float64 {
+	return 0
+}
+
+// GenerateWave renders waveFunc into a fixed-length []int16 buffer. It is an
+// offline counterpart to PlayWave: both run the same Oscillator node, one
+// pulling into a live stream, the other pulling once into a full buffer.
 func GenerateWave(waveFunc Waveform, frequency float64, amplitude float64, phase float64, sampleRate int, duration time.Duration) []int16 {
+	osc := NewOscillator(waveFunc, frequency, amplitude, phase, sampleRate)
 	numSamples := int(duration.Seconds() * float64(sampleRate))
-	wave := make([]int16, numSamples)
+	buf := renderOffline(osc, numSamples)
 
-	for i := 0; i < numSamples; i++ {
-		t := float64(i) / float64(sampleRate)
-		sample := waveFunc(t, frequency, amplitude, phase)
+	wave := make([]int16, numSamples)
+	for i, sample := range buf {
 		wave[i] = int16(sample)
 	}
 
 	return wave
 }
 
-// PlayWave plays the generated waveform using SDL2_mixer from a temporary file
-func PlayWave(wave []int16, sampleRate int) error {
-	// Write the wave to a temporary file
-	tmpfile, err := ioutil.TempFile("", "waveform_*.wav")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %v", err)
-	}
-	defer os.Remove(tmpfile.Name())
-
-	if err := WriteWAV(tmpfile.Name(), wave, sampleRate); err != nil {
-		return fmt.Errorf("failed to write wave to file: %v", err)
-	}
-
-	// Initialize SDL2 mixer
-	if err := mix.OpenAudio(sampleRate, mix.DEFAULT_FORMAT, 1, 4096); err != nil {
-		return fmt.Errorf("failed to initialize audio: %v", err)
-	}
-	defer mix.CloseAudio()
-
-	// Load the temporary wave file
-	chunk, err := mix.LoadWAV(tmpfile.Name())
-	if err != nil {
-		return fmt.Errorf("failed to load WAV: %v", err)
-	}
-	defer chunk.Free()
-
-	// Play the waveform
-	if _, err := chunk.Play(-1, 0); err != nil {
-		return fmt.Errorf("failed to play wave: %v", err)
-	}
-
-	time.Sleep(duration) // Allow the sound to play for the duration
-
-	return nil
-}
-
-// WriteWAV writes the generated waveform to a WAV file
-func WriteWAV(filename string, wave []int16, sampleRate int) error {
-	buffer := &audio.IntBuffer{
-		Data:           make([]int, len(wave)),
-		Format:         &audio.Format{SampleRate: sampleRate, NumChannels: 1},
-		SourceBitDepth: bitDepth,
-	}
-
-	for i, sample := range wave {
-		buffer.Data[i] = int(sample)
-	}
-
-	outFile, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %v", filename, err)
-	}
-	defer outFile.Close()
-
-	encoder := wav.NewEncoder(outFile, sampleRate, bitDepth, 1, 1)
-	if err := encoder.Write(buffer); err != nil {
-		return fmt.Errorf("failed to write WAV data: %v", err)
-	}
-	if err := encoder.Close(); err != nil {
-		return fmt.Errorf("failed to close WAV encoder: %v", err)
-	}
-
-	fmt.Printf("Written %s\n", filename)
-	return nil
+// writeMonoWAV writes a single-channel []int16 waveform to filename as a
+// 16-bit PCM WAV file, through the shared PCMEncoder.
+func writeMonoWAV(filename string, wave []int16, sampleRate int) error {
+	return PCMEncoder{}.WriteWAVInt16(filename, [][]float64{int16ToFloat64(wave)}, sampleRate)
 }
 
-// CombineWaves combines multiple waveforms into one by summing their values
-func CombineWaves(waves ...[]int16) []int16 {
-	if len(waves) == 0 {
-		return nil
-	}
-
-	numSamples := len(waves[0])
-	combined := make([]int16, numSamples)
-
-	for i := 0; i < numSamples; i++ {
-		sum := int32(0)
-		for _, wave := range waves {
-			sum += int32(wave[i])
-		}
-		// Ensure the combined value does not exceed the int16 range
-		if sum > math.MaxInt16 {
-			sum = math.MaxInt16
-		} else if sum < math.MinInt16 {
-			sum = math.MinInt16
+// main routes to the gen and play subcommands; with no subcommand it falls
+// back to the original three-waveform demo.
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "gen":
+			if err := genMain(os.Args[2:]); err != nil {
+				log.Fatalf("gen: %v", err)
+			}
+			return
+		case "play":
+			if err := playMain(os.Args[2:]); err != nil {
+				log.Fatalf("play: %v", err)
+			}
+			return
 		}
-		combined[i] = int16(sum)
 	}
 
-	return combined
+	demoMain()
 }
 
-func main() {
+func demoMain() {
 	frequency := 220.0
 	amplitude := 0.8 * amplitude // 80% of full amplitude
 	phase := 0.0
@@ -158,10 +97,11 @@ func main() {
 	sineWave := GenerateWave(SineWave, frequency, amplitude, phase, sampleRate, duration)
 	// Play and then write the sine wave
 	fmt.Println("Playing sine wave...")
-	if err := PlayWave(sineWave, sampleRate); err != nil {
+	sineOsc := NewOscillator(SineWave, frequency, amplitude, phase, sampleRate)
+	if err := PlayWave(sineOsc, sampleRate, duration); err != nil {
 		log.Fatalf("Error playing sine wave: %v", err)
 	}
-	if err := WriteWAV("sine_wave.wav", sineWave, sampleRate); err != nil {
+	if err := writeMonoWAV("sine_wave.wav", sineWave, sampleRate); err != nil {
 		log.Fatalf("Error writing sine_wave.wav: %v", err)
 	}
 
@@ -169,10 +109,11 @@ func main() {
 	squareWave := GenerateWave(SquareWave, frequency, amplitude, phase, sampleRate, duration)
 	// Play and then write the square wave
 	fmt.Println("Playing square wave...")
-	if err := PlayWave(squareWave, sampleRate); err != nil {
+	squareOsc := NewOscillator(SquareWave, frequency, amplitude, phase, sampleRate)
+	if err := PlayWave(squareOsc, sampleRate, duration); err != nil {
 		log.Fatalf("Error playing square wave: %v", err)
 	}
-	if err := WriteWAV("square_wave.wav", squareWave, sampleRate); err != nil {
+	if err := writeMonoWAV("square_wave.wav", squareWave, sampleRate); err != nil {
 		log.Fatalf("Error writing square_wave.wav: %v", err)
 	}
 
@@ -180,23 +121,34 @@ func main() {
 	triangleWave := GenerateWave(TriangleWave, frequency, amplitude, phase, sampleRate, duration)
 	// Play and then write the triangle wave
 	fmt.Println("Playing triangle wave...")
-	if err := PlayWave(triangleWave, sampleRate); err != nil {
+	triangleOsc := NewOscillator(TriangleWave, frequency, amplitude, phase, sampleRate)
+	if err := PlayWave(triangleOsc, sampleRate, duration); err != nil {
 		log.Fatalf("Error playing triangle wave: %v", err)
 	}
-	if err := WriteWAV("triangle_wave.wav", triangleWave, sampleRate); err != nil {
+	if err := writeMonoWAV("triangle_wave.wav", triangleWave, sampleRate); err != nil {
 		log.Fatalf("Error writing triangle_wave.wav: %v", err)
 	}
 
 	// Combine all three waveforms
 	fmt.Println("Combining all waveforms...")
-	combinedWave := CombineWaves(sineWave, squareWave, triangleWave)
+	mixed := CombineWaves(DefaultMixOptions(),
+		int16ToFloat64(sineWave), int16ToFloat64(squareWave), int16ToFloat64(triangleWave))
+	combinedWave := make([]int16, len(mixed))
+	for i, sample := range mixed {
+		combinedWave[i] = QuantizeInt16(sample)
+	}
 
 	// Play and write the combined waveform
 	fmt.Println("Playing combined waveform...")
-	if err := PlayWave(combinedWave, sampleRate); err != nil {
+	combinedMixer := NewMixer(
+		NewOscillator(SineWave, frequency, amplitude, phase, sampleRate),
+		NewOscillator(SquareWave, frequency, amplitude, phase, sampleRate),
+		NewOscillator(TriangleWave, frequency, amplitude, phase, sampleRate),
+	)
+	if err := PlayWave(combinedMixer, sampleRate, duration); err != nil {
 		log.Fatalf("Error playing combined waveform: %v", err)
 	}
-	if err := WriteWAV("combined_wave.wav", combinedWave, sampleRate); err != nil {
+	if err := writeMonoWAV("combined_wave.wav", combinedWave, sampleRate); err != nil {
 		log.Fatalf("Error writing combined_wave.wav: %v", err)
 	}
 
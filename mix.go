@@ -0,0 +1,166 @@
+package main
+
+import "math"
+
+// fullScaleInt16 is digital full scale in this package's existing
+// int16-range sample units (the same units Waveform/Oscillator already use).
+const fullScaleInt16 = float64(math.MaxInt16)
+
+// MixMode selects how CombineWaves scales its summed buffer before
+// quantization.
+type MixMode int
+
+const (
+	// MixPeakNormalize scales the summed buffer so its peak sample hits
+	// full scale. This is the default: it never clips and uses all
+	// available headroom.
+	MixPeakNormalize MixMode = iota
+	// MixRMSNormalize scales the summed buffer so its RMS level hits
+	// TargetDBFS.
+	MixRMSNormalize
+	// MixSoftClip leaves level alone and instead runs the buffer through a
+	// tanh soft-clipper with the given Knee, trading a little harmonic
+	// distortion for no normalization-induced pumping.
+	MixSoftClip
+)
+
+// MixOptions configures how CombineWaves sums and scales its inputs.
+type MixOptions struct {
+	Gains      []float64 // per-source gain applied before summing; nil means unity gain
+	Mode       MixMode
+	TargetDBFS float64 // used by MixRMSNormalize, e.g. -3
+	Knee       float64 // soft-clip knee used by MixSoftClip; <= 0 uses 1.0
+}
+
+// DefaultMixOptions returns headroom-friendly defaults: unity gains and
+// peak normalization.
+func DefaultMixOptions() MixOptions {
+	return MixOptions{Mode: MixPeakNormalize}
+}
+
+// CombineWaves sums waves (each a float64 sample buffer in the same units
+// as Waveform/Oscillator output) with the per-source gain from opts.Gains,
+// then scales the result according to opts.Mode. It operates entirely in
+// float64; use QuantizeInt16/QuantizeInt24/QuantizeFloat32 to convert the
+// result to an output sample format.
+func CombineWaves(opts MixOptions, waves ...[]float64) []float64 {
+	if len(waves) == 0 {
+		return nil
+	}
+
+	numSamples := len(waves[0])
+	combined := make([]float64, numSamples)
+
+	for i, wave := range waves {
+		gain := 1.0
+		if i < len(opts.Gains) {
+			gain = opts.Gains[i]
+		}
+		for j := 0; j < numSamples; j++ {
+			combined[j] += wave[j] * gain
+		}
+	}
+
+	switch opts.Mode {
+	case MixRMSNormalize:
+		applyRMSNormalize(combined, opts.TargetDBFS)
+	case MixSoftClip:
+		applySoftClip(combined, opts.Knee)
+	default:
+		applyPeakNormalize(combined)
+	}
+
+	return combined
+}
+
+// applyPeakNormalize scales buf so its peak absolute sample hits full scale.
+func applyPeakNormalize(buf []float64) {
+	peak := 0.0
+	for _, s := range buf {
+		if a := math.Abs(s); a > peak {
+			peak = a
+		}
+	}
+	if peak == 0 {
+		return
+	}
+	scale := fullScaleInt16 / peak
+	for i := range buf {
+		buf[i] *= scale
+	}
+}
+
+// applyRMSNormalize scales buf so its RMS level hits targetDBFS relative to
+// full scale.
+func applyRMSNormalize(buf []float64, targetDBFS float64) {
+	if len(buf) == 0 {
+		return
+	}
+	sumSq := 0.0
+	for _, s := range buf {
+		sumSq += s * s
+	}
+	rms := math.Sqrt(sumSq / float64(len(buf)))
+	if rms == 0 {
+		return
+	}
+	targetRMS := fullScaleInt16 * math.Pow(10, targetDBFS/20)
+	scale := targetRMS / rms
+	for i := range buf {
+		buf[i] *= scale
+	}
+}
+
+// applySoftClip runs buf through a tanh soft-clipper with the given knee,
+// expressed as a fraction of full scale.
+func applySoftClip(buf []float64, knee float64) {
+	if knee <= 0 {
+		knee = 1.0
+	}
+	threshold := fullScaleInt16 * knee
+	for i, s := range buf {
+		buf[i] = math.Tanh(s/threshold) * threshold
+	}
+}
+
+// QuantizeInt16 clamps and converts a full-scale float64 sample to int16.
+func QuantizeInt16(sample float64) int16 {
+	if sample > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if sample < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(sample)
+}
+
+// QuantizeInt24 clamps and converts a full-scale float64 sample to a 24-bit
+// two's-complement integer.
+func QuantizeInt24(sample float64) int32 {
+	const maxInt24 = 1<<23 - 1
+	const minInt24 = -1 << 23
+	scaled := sample * (maxInt24 / fullScaleInt16)
+	if scaled > maxInt24 {
+		return maxInt24
+	}
+	if scaled < minInt24 {
+		return minInt24
+	}
+	return int32(scaled)
+}
+
+// QuantizeFloat32 converts a full-scale float64 sample to the [-1, 1] range
+// IEEE float32 PCM expects.
+func QuantizeFloat32(sample float64) float32 {
+	return float32(sample / fullScaleInt16)
+}
+
+// int16ToFloat64 widens a legacy []int16 sample buffer for use with the
+// float64 mixer.
+func int16ToFloat64(wave []int16) []float64 {
+	out := make([]float64, len(wave))
+	for i, s := range wave {
+		out[i] = float64(s)
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCombineWavesPeakNormalize(t *testing.T) {
+	a := []float64{fullScaleInt16 * 0.5, -fullScaleInt16 * 0.5}
+	b := []float64{fullScaleInt16 * 0.5, fullScaleInt16 * 0.5}
+
+	mixed := CombineWaves(DefaultMixOptions(), a, b)
+
+	// Unmixed sum is [fullScale, 0], which already sits at peak, so
+	// normalization should leave it unchanged.
+	if math.Abs(mixed[0]-fullScaleInt16) > 1e-6 {
+		t.Errorf("mixed[0] = %v, want %v", mixed[0], fullScaleInt16)
+	}
+	if math.Abs(mixed[1]) > 1e-6 {
+		t.Errorf("mixed[1] = %v, want 0", mixed[1])
+	}
+}
+
+func TestCombineWavesGains(t *testing.T) {
+	a := []float64{fullScaleInt16}
+	b := []float64{fullScaleInt16}
+
+	opts := MixOptions{Gains: []float64{1, 0}, Mode: MixPeakNormalize}
+	mixed := CombineWaves(opts, a, b)
+
+	if math.Abs(mixed[0]-fullScaleInt16) > 1e-6 {
+		t.Errorf("mixed[0] = %v, want %v (b should be silenced by zero gain)", mixed[0], fullScaleInt16)
+	}
+}
+
+func TestCombineWavesSoftClipStaysBounded(t *testing.T) {
+	huge := []float64{fullScaleInt16 * 10}
+
+	mixed := CombineWaves(MixOptions{Mode: MixSoftClip, Knee: 1}, huge)
+
+	if math.Abs(mixed[0]) >= fullScaleInt16 {
+		t.Errorf("soft-clipped sample = %v, want magnitude < %v", mixed[0], fullScaleInt16)
+	}
+}
+
+func TestCombineWavesRMSNormalize(t *testing.T) {
+	wave := []float64{fullScaleInt16, -fullScaleInt16}
+
+	mixed := CombineWaves(MixOptions{Mode: MixRMSNormalize, TargetDBFS: -6}, wave)
+
+	sumSq := 0.0
+	for _, s := range mixed {
+		sumSq += s * s
+	}
+	rms := math.Sqrt(sumSq / float64(len(mixed)))
+	wantRMS := fullScaleInt16 * math.Pow(10, -6.0/20)
+	if math.Abs(rms-wantRMS) > 1 {
+		t.Errorf("rms = %v, want %v", rms, wantRMS)
+	}
+}
+
+func TestQuantizeInt16Clamps(t *testing.T) {
+	if got := QuantizeInt16(fullScaleInt16 * 2); got != math.MaxInt16 {
+		t.Errorf("QuantizeInt16(overflow) = %d, want %d", got, math.MaxInt16)
+	}
+	if got := QuantizeInt16(-fullScaleInt16 * 2); got != math.MinInt16 {
+		t.Errorf("QuantizeInt16(underflow) = %d, want %d", got, math.MinInt16)
+	}
+}
+
+func TestQuantizeInt24Clamps(t *testing.T) {
+	const maxInt24 = 1<<23 - 1
+	if got := QuantizeInt24(fullScaleInt16 * 2); got != maxInt24 {
+		t.Errorf("QuantizeInt24(overflow) = %d, want %d", got, maxInt24)
+	}
+}
+
+func TestQuantizeFloat32FullScale(t *testing.T) {
+	if got := QuantizeFloat32(fullScaleInt16); math.Abs(float64(got)-1.0) > 1e-6 {
+		t.Errorf("QuantizeFloat32(fullScale) = %v, want 1.0", got)
+	}
+}
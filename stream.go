@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// framesPerBuffer is the number of samples pulled from the source graph on
+// each portaudio callback.
+const framesPerBuffer = 1024
+
+// Source is a pull-based audio node. Render fills buf with the next
+// len(buf) samples, advancing any internal state (phase, envelope stage,
+// etc). Nodes are composed into a graph (oscillators, mixers, envelopes)
+// that a Stream or an offline renderer pulls from identically.
+type Source interface {
+	Render(buf []float32)
+}
+
+// Oscillator is a stateful Source wrapping a Waveform function. Unlike the
+// old one-shot slice generators, it remembers how much time has elapsed
+// across calls to Render, so playback can continue indefinitely and
+// Frequency/Phase can be changed live between buffers via SetFrequency/
+// SetPhase, which are safe to call from a goroutine other than the one
+// driving Render (e.g. from the caller while a Stream's portaudio callback
+// is pulling from this oscillator on its own OS thread).
+type Oscillator struct {
+	Wave       Waveform
+	Amplitude  float64
+	SampleRate int
+
+	frequencyBits uint64 // atomic; float64 bits, use Frequency/SetFrequency
+	phaseBits     uint64 // atomic; float64 bits, use Phase/SetPhase
+
+	t float64 // seconds elapsed, advanced every Render call
+}
+
+// NewOscillator creates an Oscillator driven by wave, starting at t=0.
+func NewOscillator(wave Waveform, frequency, amplitude, phase float64, sampleRate int) *Oscillator {
+	o := &Oscillator{
+		Wave:       wave,
+		Amplitude:  amplitude,
+		SampleRate: sampleRate,
+	}
+	o.SetFrequency(frequency)
+	o.SetPhase(phase)
+	return o
+}
+
+// Frequency returns o's current frequency in Hz.
+func (o *Oscillator) Frequency() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&o.frequencyBits))
+}
+
+// SetFrequency changes o's frequency. Safe to call concurrently with Render.
+func (o *Oscillator) SetFrequency(frequency float64) {
+	atomic.StoreUint64(&o.frequencyBits, math.Float64bits(frequency))
+}
+
+// Phase returns o's current phase offset in radians.
+func (o *Oscillator) Phase() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&o.phaseBits))
+}
+
+// SetPhase changes o's phase offset. Safe to call concurrently with Render.
+func (o *Oscillator) SetPhase(phase float64) {
+	atomic.StoreUint64(&o.phaseBits, math.Float64bits(phase))
+}
+
+// Render fills buf with the next len(buf) samples and advances o's clock.
+func (o *Oscillator) Render(buf []float32) {
+	dt := 1.0 / float64(o.SampleRate)
+	frequency, phase := o.Frequency(), o.Phase()
+	for i := range buf {
+		buf[i] = float32(o.Wave(o.t, frequency, o.Amplitude, phase))
+		o.t += dt
+	}
+}
+
+// Mixer is a Source that sums the output of several child Sources.
+type Mixer struct {
+	Sources []Source
+
+	scratch []float32 // reused across Render calls to avoid hot-path allocation
+}
+
+// NewMixer creates a Mixer over the given child sources.
+func NewMixer(sources ...Source) *Mixer {
+	return &Mixer{Sources: sources}
+}
+
+// Render sums each child's contribution into buf.
+func (m *Mixer) Render(buf []float32) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	if len(m.scratch) != len(buf) {
+		m.scratch = make([]float32, len(buf))
+	}
+	for _, src := range m.Sources {
+		src.Render(m.scratch)
+		for i := range buf {
+			buf[i] += m.scratch[i]
+		}
+	}
+}
+
+// Stream drives a portaudio output stream by repeatedly pulling buffers
+// from a root Source on every callback.
+type Stream struct {
+	pa     *portaudio.Stream
+	source Source
+}
+
+// NewStream opens the default mono output stream at sampleRate, pulling
+// from source on every callback.
+func NewStream(source Source, sampleRate int, framesPerBuffer int) (*Stream, error) {
+	s := &Stream{source: source}
+
+	stream, err := portaudio.OpenDefaultStream(0, 1, float64(sampleRate), framesPerBuffer, s.callback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open portaudio stream: %v", err)
+	}
+	s.pa = stream
+
+	return s, nil
+}
+
+// callback is the pull-based portaudio handler: it renders the root source
+// into portaudio's output buffer, then scales the samples from the graph's
+// int16-range units down to the [-1, 1] range float32 output expects.
+func (s *Stream) callback(out []float32) {
+	s.source.Render(out)
+	for i := range out {
+		out[i] /= float32(fullScaleInt16)
+	}
+}
+
+// Start begins streaming audio to the output device.
+func (s *Stream) Start() error {
+	return s.pa.Start()
+}
+
+// Stop halts streaming without closing the underlying device.
+func (s *Stream) Stop() error {
+	return s.pa.Stop()
+}
+
+// Close releases the underlying portaudio stream.
+func (s *Stream) Close() error {
+	return s.pa.Close()
+}
+
+// PlayWave streams source through the default audio output device for dur.
+// This replaces the old write-to-tempfile-then-SDL2_mixer pipeline: samples
+// are pulled live from the node graph instead of being pre-rendered to a
+// WAV file and handed to an external player.
+func PlayWave(source Source, sampleRate int, dur time.Duration) error {
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize portaudio: %v", err)
+	}
+	defer portaudio.Terminate()
+
+	stream, err := NewStream(source, sampleRate, framesPerBuffer)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Start(); err != nil {
+		return fmt.Errorf("failed to start stream: %v", err)
+	}
+	defer stream.Stop()
+
+	time.Sleep(dur)
+
+	return nil
+}
+
+// renderOffline pulls numSamples out of source in one shot, for code paths
+// (GenerateWave) that need a complete buffer rather than a live
+// stream. It drives the exact same Source graph PlayWave does.
+func renderOffline(source Source, numSamples int) []float32 {
+	buf := make([]float32, numSamples)
+	source.Render(buf)
+	return buf
+}